@@ -4,26 +4,42 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/pprof"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/emicklei/go-restful"
 	"github.com/golang/glog"
 
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/client"
+	"k8s.io/kubernetes/pkg/client/dynamic"
+	"k8s.io/kubernetes/pkg/client/informers"
+	"k8s.io/kubernetes/pkg/client/leaderelection"
 	"k8s.io/kubernetes/pkg/client/record"
 	"k8s.io/kubernetes/pkg/cloudprovider/nodecontroller"
+	"k8s.io/kubernetes/pkg/controller/garbagecollector"
+	persistentvolumecontroller "k8s.io/kubernetes/pkg/controller/persistentvolume"
 	"k8s.io/kubernetes/pkg/controller/replication"
+	"k8s.io/kubernetes/pkg/healthz"
 	"k8s.io/kubernetes/pkg/master"
 	"k8s.io/kubernetes/pkg/namespace"
 	"k8s.io/kubernetes/pkg/resourcequota"
 	"k8s.io/kubernetes/pkg/service"
 	"k8s.io/kubernetes/pkg/util"
+	"k8s.io/kubernetes/pkg/util/wait"
 	"k8s.io/kubernetes/pkg/volume"
+	"k8s.io/kubernetes/pkg/volume/aws_ebs"
+	"k8s.io/kubernetes/pkg/volume/cinder"
+	"k8s.io/kubernetes/pkg/volume/gce_pd"
 	"k8s.io/kubernetes/pkg/volume/host_path"
 	"k8s.io/kubernetes/pkg/volume/nfs"
 	"k8s.io/kubernetes/pkg/volumeclaimbinder"
 	"k8s.io/kubernetes/plugin/pkg/scheduler"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
 	_ "k8s.io/kubernetes/plugin/pkg/scheduler/algorithmprovider"
 	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
 	latestschedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api/latest"
@@ -34,6 +50,16 @@ const (
 	KubeAPIPrefix        = "/api"
 	KubeAPIPrefixV1Beta3 = "/api/v1beta3"
 	KubeAPIPrefixV1      = "/api/v1"
+
+	// defaultInformerResyncPeriod is how often the shared informers started by
+	// InstallAPI do a full relist against the apiserver, independent of any
+	// watch events they receive in between.
+	defaultInformerResyncPeriod = 10 * time.Minute
+
+	// schedulerHealthCheckPeriod is the sync period RunScheduler's /healthz
+	// subcheck is judged against (unhealthy after twice this without a
+	// sync), since the scheduler has no notion of a sync period of its own.
+	schedulerHealthCheckPeriod = 1 * time.Minute
 )
 
 // InstallAPI starts a Kubernetes master and registers the supported REST APIs
@@ -44,6 +70,13 @@ func (c *MasterConfig) InstallAPI(container *restful.Container) []string {
 	c.Master.RestfulContainer = container
 	_ = master.New(c.Master)
 
+	if c.SharedInformers == nil {
+		c.SharedInformers = informers.NewSharedInformerFactory(c.KubeClient, defaultInformerResyncPeriod)
+	}
+	c.SharedInformers.Start(util.NeverStop)
+	glog.Infof("Waiting for shared informer caches to sync")
+	c.SharedInformers.WaitForCacheSync(util.NeverStop)
+
 	messages := []string{}
 	if c.Master.EnableV1Beta3 {
 		messages = append(messages, fmt.Sprintf("Started Kubernetes API at %%s%s (deprecated)", KubeAPIPrefixV1Beta3))
@@ -55,112 +88,421 @@ func (c *MasterConfig) InstallAPI(container *restful.Container) []string {
 	return messages
 }
 
+// InstallDebug registers /healthz into container, with a named subcheck for
+// every controller that has called registerControllerHealthCheck plus the
+// standard /healthz/ping, so operators can alert on a stuck controller
+// without scraping logs. When enableProfiling is set it additionally mounts
+// net/http/pprof's handlers under /debug/pprof.
+func (c *MasterConfig) InstallDebug(container *restful.Container, enableProfiling bool) {
+	// InstallHandler only adds the ping check itself when given no checks.
+	checks := append([]healthz.HealthzChecker{healthz.PingHealthz}, c.HealthChecks...)
+	healthz.InstallHandler(container.ServeMux, checks...)
+
+	if !enableProfiling {
+		return
+	}
+	container.ServeMux.HandleFunc("/debug/pprof/", pprof.Index)
+	container.ServeMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	container.ServeMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	container.ServeMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	container.ServeMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// controllerHealthCheck reports a controller unhealthy to /healthz once it
+// has gone more than twice its configured sync period without completing a
+// sync while this process holds that controller's leader election lease.
+// touch must be called by the controller's sync loop each time it completes
+// a pass, and setLeading must track leadership changes so a passive standby
+// (which never touches the check) isn't reported as stuck. A check whose
+// setLeading(true) has never been called reports healthy regardless of sync
+// age; with leader election disabled, runControllerWithLeaderElection calls
+// setLeading(true) up front, so the check is judged on sync age alone.
+type controllerHealthCheck struct {
+	name       string
+	syncPeriod time.Duration
+	lastSync   int64 // unix nanoseconds, read/written via sync/atomic
+	leading    int32 // 0 or 1, read/written via sync/atomic
+}
+
+// registerControllerHealthCheck builds a controllerHealthCheck for name,
+// appends it to c.HealthChecks so InstallDebug picks it up, and returns it so
+// the caller can touch() and setLeading() it as leadership and syncs change.
+func (c *MasterConfig) registerControllerHealthCheck(name string, syncPeriod time.Duration) *controllerHealthCheck {
+	check := &controllerHealthCheck{name: name, syncPeriod: syncPeriod, lastSync: time.Now().UnixNano()}
+	c.HealthChecks = append(c.HealthChecks, check)
+	return check
+}
+
+func (h *controllerHealthCheck) touch() {
+	atomic.StoreInt64(&h.lastSync, time.Now().UnixNano())
+}
+
+// setLeading records whether this process currently holds the controller's
+// leader election lease. It should be called true from OnStartedLeading and
+// false from OnStoppedLeading.
+func (h *controllerHealthCheck) setLeading(leading bool) {
+	var v int32
+	if leading {
+		v = 1
+		// Leadership just started (or election is disabled): reset the
+		// clock so the grace period starts now rather than at process
+		// start, before the first real sync has had a chance to land.
+		atomic.StoreInt64(&h.lastSync, time.Now().UnixNano())
+	}
+	atomic.StoreInt32(&h.leading, v)
+}
+
+func (h *controllerHealthCheck) isLeading() bool {
+	return atomic.LoadInt32(&h.leading) == 1
+}
+
+func (h *controllerHealthCheck) Name() string {
+	return h.name
+}
+
+func (h *controllerHealthCheck) Check(_ *http.Request) error {
+	if !h.isLeading() {
+		return nil
+	}
+	age := time.Since(time.Unix(0, atomic.LoadInt64(&h.lastSync)))
+	if age > 2*h.syncPeriod {
+		return fmt.Errorf("%s has not synced in %s (expected every %s)", h.name, age, h.syncPeriod)
+	}
+	return nil
+}
+
+// runControllerWithLeaderElection runs fn every time this process is
+// observed as the holder of the named lease, and stops it again when the
+// lease is lost, so that only one master in an active/passive configuration
+// ever drives the controller at a time. Losing the lease (e.g. a transient
+// renew failure) does not disable this process permanently: it keeps
+// re-entering the election so it can take the lease back over on a later
+// attempt, which is what makes this active/passive rather than active/dead.
+// The lease is recorded as an annotation on an Endpoints object in
+// kube-system named name, or LockResourceNamePrefix + "-" + name when that
+// prefix is set, renewed on the schedule configured in
+// c.ControllerManager.LeaderElection. When leader election is disabled, fn is
+// started immediately against a channel that is never closed, matching the
+// previous, election-free behavior. check, if non-nil, is kept in sync with
+// leadership changes so its /healthz subcheck reports healthy while this
+// process is a passive standby.
+func (c *MasterConfig) runControllerWithLeaderElection(name string, check *controllerHealthCheck, fn func(stopCh <-chan struct{})) {
+	if !c.ControllerManager.LeaderElection.Enabled {
+		if check != nil {
+			check.setLeading(true)
+		}
+		fn(util.NeverStop)
+		return
+	}
+
+	eventcast := record.NewBroadcaster()
+	recorder := eventcast.NewRecorder(kapi.EventSource{Component: name})
+	eventcast.StartRecordingToSink(c.KubeClient.Events(""))
+
+	lockName := name
+	if prefix := c.ControllerManager.LeaderElection.LockResourceNamePrefix; len(prefix) > 0 {
+		lockName = prefix + "-" + name
+	}
+
+	var mu sync.Mutex
+	var stopCh chan struct{}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		EndpointsMeta: kapi.ObjectMeta{
+			Namespace: "kube-system",
+			Name:      lockName,
+		},
+		Client:        c.KubeClient,
+		Identity:      c.ControllerManager.LeaderElection.Identity,
+		EventRecorder: recorder,
+		LeaseDuration: c.ControllerManager.LeaderElection.LeaseDuration,
+		RenewDeadline: c.ControllerManager.LeaderElection.RenewDeadline,
+		RetryPeriod:   c.ControllerManager.LeaderElection.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(_ <-chan struct{}) {
+				glog.Infof("%s acquired the %s/%s lease, starting", name, "kube-system", lockName)
+				if check != nil {
+					check.setLeading(true)
+				}
+
+				mu.Lock()
+				stopCh = make(chan struct{})
+				localStopCh := stopCh
+				mu.Unlock()
+
+				fn(localStopCh)
+			},
+			OnStoppedLeading: func() {
+				glog.Infof("%s lost the %s/%s lease, stopping", name, "kube-system", lockName)
+				if check != nil {
+					check.setLeading(false)
+				}
+
+				mu.Lock()
+				localStopCh := stopCh
+				stopCh = nil
+				mu.Unlock()
+
+				if localStopCh != nil {
+					close(localStopCh)
+				}
+			},
+		},
+	})
+	if err != nil {
+		glog.Fatalf("Unable to set up leader election for %s: %v", name, err)
+	}
+
+	// elector.Run returns as soon as this process stops renewing the lease;
+	// re-enter the race immediately so a lost lease can be reacquired later
+	// instead of disabling this controller on this process for good.
+	go wait.Forever(elector.Run, 0)
+}
+
 // RunNamespaceController starts the Kubernetes Namespace Manager
 func (c *MasterConfig) RunNamespaceController() {
-	namespaceController := namespace.NewNamespaceManager(c.KubeClient, c.ControllerManager.NamespaceSyncPeriod)
-	namespaceController.Run()
-	glog.Infof("Started Kubernetes Namespace Manager")
+	c.runControllerWithLeaderElection("namespace-controller", nil, func(stopCh <-chan struct{}) {
+		namespaceController := namespace.NewNamespaceManager(c.KubeClient, c.ControllerManager.NamespaceSyncPeriod)
+		namespaceController.Run(stopCh)
+		glog.Infof("Started Kubernetes Namespace Manager")
+	})
 }
 
 // RunPersistentVolumeClaimBinder starts the Kubernetes Persistent Volume Claim Binder
 func (c *MasterConfig) RunPersistentVolumeClaimBinder() {
-	binder := volumeclaimbinder.NewPersistentVolumeClaimBinder(c.KubeClient, c.ControllerManager.PVClaimBinderSyncPeriod)
-	binder.Run()
-	glog.Infof("Started Kubernetes Persistent Volume Claim Binder")
+	check := c.registerControllerHealthCheck("pv-binder", c.ControllerManager.PVClaimBinderSyncPeriod)
+	c.runControllerWithLeaderElection("persistent-volume-binder", check, func(stopCh <-chan struct{}) {
+		binder := volumeclaimbinder.NewPersistentVolumeClaimBinder(c.KubeClient, c.ControllerManager.PVClaimBinderSyncPeriod)
+		binder.SetSyncedCallback(check.touch)
+		binder.Run(stopCh)
+		glog.Infof("Started Kubernetes Persistent Volume Claim Binder")
+	})
+}
+
+// RunPersistentVolumeClaimRecycler starts the Kubernetes Persistent Volume
+// Recycler against the supplied plugin registry, so callers control exactly
+// which volume types are recyclable and with what scrub pod configuration.
+// Use DefaultPersistentVolumeRecyclerPlugins to build the registry this
+// process shipped with previously (host_path and NFS).
+func (c *MasterConfig) RunPersistentVolumeClaimRecycler(recyclerPlugins []volume.VolumePlugin) {
+	c.runControllerWithLeaderElection("persistent-volume-recycler", nil, func(stopCh <-chan struct{}) {
+		recycler, err := volumeclaimbinder.NewPersistentVolumeRecycler(c.KubeClient, c.ControllerManager.PVClaimBinderSyncPeriod, recyclerPlugins)
+		if err != nil {
+			glog.Fatalf("Could not start PersistentVolumeRecycler: %+v", err)
+		}
+		recycler.Run(stopCh)
+		glog.Infof("Started Kubernetes PersistentVolumeRecycler")
+	})
 }
 
-func (c *MasterConfig) RunPersistentVolumeClaimRecycler(recyclerImageName string) {
+// DefaultPersistentVolumeRecyclerPlugins builds the host_path and NFS
+// recycler plugins that RunPersistentVolumeClaimRecycler ran with before it
+// took a caller-supplied registry. Each plugin's scrub pod is configured from
+// conf, falling back to the recycler.sh script and the previous hardcoded
+// timeouts when conf leaves a field unset, so existing deployments keep their
+// current behavior until they opt into overriding it.
+func DefaultPersistentVolumeRecyclerPlugins(recyclerImageName string, conf VolumeConfiguration) []volume.VolumePlugin {
+	hostPathConfig, nfsConfig := defaultPersistentVolumeRecyclerConfigs(recyclerImageName, conf)
+
+	allPlugins := []volume.VolumePlugin{}
+	allPlugins = append(allPlugins, host_path.ProbeVolumePlugins(hostPathConfig)...)
+	allPlugins = append(allPlugins, nfs.ProbeVolumePlugins(nfsConfig)...)
+	return allPlugins
+}
 
-	hostPathRecycler := &volume.RecyclableVolumeConfig{
+// defaultPersistentVolumeRecyclerConfigs builds the merged host_path and NFS
+// RecyclableVolumeConfigs that DefaultPersistentVolumeRecyclerPlugins probes
+// its plugins with, split out so tests can assert on the merge directly.
+func defaultPersistentVolumeRecyclerConfigs(recyclerImageName string, conf VolumeConfiguration) (hostPathConfig, nfsConfig *volume.RecyclableVolumeConfig) {
+	hostPathConfig = recyclableVolumeConfigOrDefault(conf.HostPathRecycler, volume.RecyclableVolumeConfig{
 		ImageName: recyclerImageName,
 		Command:   []string{"/usr/share/openshift/scripts/volumes/recycler.sh"},
 		Args:      []string{"/scrub"},
 		Timeout:   int64(60),
-	}
+	})
 
-	nfsRecycler := &volume.RecyclableVolumeConfig{
+	nfsConfig = recyclableVolumeConfigOrDefault(conf.NFSRecycler, volume.RecyclableVolumeConfig{
 		ImageName: recyclerImageName,
 		Command:   []string{"/usr/share/openshift/scripts/volumes/recycler.sh"},
 		Args:      []string{"/scrub"},
 		Timeout:   int64(300),
+	})
+
+	return hostPathConfig, nfsConfig
+}
+
+// recyclableVolumeConfigOrDefault merges configured over def field by field,
+// substituting def's value for any field configured leaves at its zero
+// value, so an operator only has to set the fields they want to override in
+// VolumeConfiguration.
+func recyclableVolumeConfigOrDefault(configured, def volume.RecyclableVolumeConfig) *volume.RecyclableVolumeConfig {
+	merged := def
+	if configured.ImageName != "" {
+		merged.ImageName = configured.ImageName
+	}
+	if len(configured.Command) > 0 {
+		merged.Command = configured.Command
+	}
+	if len(configured.Args) > 0 {
+		merged.Args = configured.Args
+	}
+	if configured.Timeout != 0 {
+		merged.Timeout = configured.Timeout
+	}
+	return &merged
+}
+
+// RunPersistentVolumeProvisioner starts the Kubernetes Persistent Volume
+// Provisioner using whichever of the AWS EBS, GCE PD, or Cinder provisioner
+// plugins matches c.CloudProvider. Provisioning is skipped entirely when the
+// configured cloud provider does not supply a matching plugin.
+func (c *MasterConfig) RunPersistentVolumeProvisioner() {
+	if c.CloudProvider == nil {
+		glog.V(2).Infof("No cloud provider configured, persistent volumes will not be dynamically provisioned")
+		return
 	}
 
 	allPlugins := []volume.VolumePlugin{}
-	allPlugins = append(allPlugins, host_path.ProbeVolumePlugins(hostPathRecycler)...)
-	allPlugins = append(allPlugins, nfs.ProbeVolumePlugins(nfsRecycler)...)
+	allPlugins = append(allPlugins, aws_ebs.ProbeVolumePlugins()...)
+	allPlugins = append(allPlugins, gce_pd.ProbeVolumePlugins()...)
+	allPlugins = append(allPlugins, cinder.ProbeVolumePlugins()...)
 
-	recycler, err := volumeclaimbinder.NewPersistentVolumeRecycler(c.KubeClient, c.ControllerManager.PVClaimBinderSyncPeriod, allPlugins)
-	if err != nil {
-		glog.Fatalf("Could not start PersistentVolumeRecycler: %+v", err)
-	}
-	recycler.Run()
-	glog.Infof("Started Kubernetes PersistentVolumeRecycler")
+	c.runControllerWithLeaderElection("persistent-volume-provisioner", nil, func(stopCh <-chan struct{}) {
+		controller, err := persistentvolumecontroller.NewPersistentVolumeProvisionerController(
+			c.KubeClient,
+			c.ControllerManager.PVClaimBinderSyncPeriod,
+			c.ControllerManager.ClusterName,
+			allPlugins,
+			c.CloudProvider,
+		)
+		if err != nil {
+			if _, ok := err.(*persistentvolumecontroller.NoProvisionerFoundErr); ok {
+				glog.V(2).Infof("No persistent volume provisioner found for the configured cloud provider, skipping")
+				return
+			}
+			glog.Fatalf("Could not start PersistentVolumeProvisioner: %+v", err)
+		}
+		controller.Run(stopCh)
+		glog.Infof("Started Kubernetes PersistentVolumeProvisioner")
+	})
 }
 
 // RunReplicationController starts the Kubernetes replication controller sync loop
 func (c *MasterConfig) RunReplicationController(client *client.Client) {
-	controllerManager := replication.NewReplicationManager(client, replication.BurstReplicas)
-	go controllerManager.Run(c.ControllerManager.ConcurrentRCSyncs, util.NeverStop)
-	glog.Infof("Started Kubernetes Replication Manager")
+	check := c.registerControllerHealthCheck("replication-controller", c.ControllerManager.NodeSyncPeriod)
+	c.runControllerWithLeaderElection("replication-controller", check, func(stopCh <-chan struct{}) {
+		controllerManager := replication.NewReplicationManager(client, c.SharedInformers.Pods().Informer(), replication.BurstReplicas)
+		controllerManager.SetSyncedCallback(check.touch)
+		go controllerManager.Run(c.ControllerManager.ConcurrentRCSyncs, stopCh)
+		glog.Infof("Started Kubernetes Replication Manager")
+	})
 }
 
 // RunEndpointController starts the Kubernetes replication controller sync loop
 func (c *MasterConfig) RunEndpointController() {
-	endpoints := service.NewEndpointController(c.KubeClient)
-	go endpoints.Run(c.ControllerManager.ConcurrentEndpointSyncs, util.NeverStop)
+	check := c.registerControllerHealthCheck("endpoint-controller", c.ControllerManager.NodeSyncPeriod)
+	c.runControllerWithLeaderElection("endpoint-controller", check, func(stopCh <-chan struct{}) {
+		endpoints := service.NewEndpointController(c.KubeClient, c.SharedInformers.Pods().Informer())
+		endpoints.SetSyncedCallback(check.touch)
+		go endpoints.Run(c.ControllerManager.ConcurrentEndpointSyncs, stopCh)
 
-	glog.Infof("Started Kubernetes Endpoint Controller")
+		glog.Infof("Started Kubernetes Endpoint Controller")
+	})
 }
 
 // RunScheduler starts the Kubernetes scheduler
 func (c *MasterConfig) RunScheduler() {
-	config, err := c.createSchedulerConfig()
-	if err != nil {
-		glog.Fatalf("Unable to start scheduler: %v", err)
-	}
-	eventcast := record.NewBroadcaster()
-	config.Recorder = eventcast.NewRecorder(kapi.EventSource{Component: "scheduler"})
-	eventcast.StartRecordingToSink(c.KubeClient.Events(""))
+	check := c.registerControllerHealthCheck("scheduler", schedulerHealthCheckPeriod)
+	c.runControllerWithLeaderElection("scheduler", check, func(stopCh <-chan struct{}) {
+		config, err := c.createSchedulerConfig()
+		if err != nil {
+			glog.Fatalf("Unable to start scheduler: %v", err)
+		}
+		eventcast := record.NewBroadcaster()
+		config.Recorder = eventcast.NewRecorder(kapi.EventSource{Component: "scheduler"})
+		eventcast.StartRecordingToSink(c.KubeClient.Events(""))
+		config.SyncedCallback = check.touch
 
-	s := scheduler.New(config)
-	s.Run()
-	glog.Infof("Started Kubernetes Scheduler")
+		s := scheduler.New(config)
+		s.Run(stopCh)
+		glog.Infof("Started Kubernetes Scheduler")
+	})
 }
 
 // RunResourceQuotaManager starts the resource quota manager
 func (c *MasterConfig) RunResourceQuotaManager() {
-	resourceQuotaManager := resourcequota.NewResourceQuotaManager(c.KubeClient)
-	resourceQuotaManager.Run(c.ControllerManager.ResourceQuotaSyncPeriod)
+	check := c.registerControllerHealthCheck("resource-quota-manager", c.ControllerManager.ResourceQuotaSyncPeriod)
+	c.runControllerWithLeaderElection("resource-quota-manager", check, func(stopCh <-chan struct{}) {
+		resourceQuotaManager := resourcequota.NewResourceQuotaManager(c.KubeClient, c.SharedInformers.Pods().Informer())
+		resourceQuotaManager.SetSyncedCallback(check.touch)
+		resourceQuotaManager.Run(c.ControllerManager.ResourceQuotaSyncPeriod, stopCh)
+	})
 }
 
 // RunNodeController starts the node controller
 func (c *MasterConfig) RunNodeController() {
-	s := c.ControllerManager
-	controller := nodecontroller.NewNodeController(
-		c.CloudProvider,
-		c.KubeClient,
-		s.RegisterRetryCount,
-		s.PodEvictionTimeout,
+	check := c.registerControllerHealthCheck("node-controller", c.ControllerManager.NodeSyncPeriod)
+	c.runControllerWithLeaderElection("node-controller", check, func(stopCh <-chan struct{}) {
+		s := c.ControllerManager
+		controller := nodecontroller.NewNodeController(
+			c.CloudProvider,
+			c.KubeClient,
+			c.SharedInformers.Nodes().Informer(),
+			s.RegisterRetryCount,
+			s.PodEvictionTimeout,
 
-		nodecontroller.NewPodEvictor(util.NewTokenBucketRateLimiter(s.DeletingPodsQps, s.DeletingPodsBurst)),
+			nodecontroller.NewPodEvictor(util.NewTokenBucketRateLimiter(s.DeletingPodsQps, s.DeletingPodsBurst)),
 
-		s.NodeMonitorGracePeriod,
-		s.NodeStartupGracePeriod,
-		s.NodeMonitorPeriod,
+			s.NodeMonitorGracePeriod,
+			s.NodeStartupGracePeriod,
+			s.NodeMonitorPeriod,
 
-		(*net.IPNet)(&s.ClusterCIDR),
-		s.AllocateNodeCIDRs,
-	)
-	controller.Run(s.NodeSyncPeriod)
+			(*net.IPNet)(&s.ClusterCIDR),
+			s.AllocateNodeCIDRs,
+		)
+		controller.SetSyncedCallback(check.touch)
+		controller.Run(s.NodeSyncPeriod, stopCh)
 
-	glog.Infof("Started Kubernetes Node Controller")
+		glog.Infof("Started Kubernetes Node Controller")
+	})
+}
+
+// RunGarbageCollectorController starts the garbage collector, which watches
+// metadata.ownerReferences across the server-preferred resources and deletes
+// dependents whose owners no longer exist, honoring each dependent's
+// requested deletionPropagation (Orphan, Background, or Foreground). It is a
+// no-op unless cascading deletion is enabled via ControllerManager.
+func (c *MasterConfig) RunGarbageCollectorController() {
+	if !c.ControllerManager.EnableGarbageCollector {
+		return
+	}
+
+	c.runControllerWithLeaderElection("garbage-collector-controller", nil, func(stopCh <-chan struct{}) {
+		preferredResources, err := c.KubeClient.Discovery().ServerPreferredResources()
+		if err != nil {
+			glog.Errorf("Failed to get preferred resources from the apiserver, garbage collector will only handle the resources it could discover: %v", err)
+		}
+
+		// one pool with a metadata-only negotiated serializer for cheap
+		// list/watch across every resource kind, and one with the full
+		// codec for the deletes themselves.
+		metaOnlyClientPool := dynamic.NewClientPoolForMetadata(c.KubeClient.Config)
+		clientPool := dynamic.NewClientPool(c.KubeClient.Config)
+
+		gc, err := garbagecollector.NewGarbageCollector(metaOnlyClientPool, clientPool, preferredResources)
+		if err != nil {
+			glog.Fatalf("Unable to start garbage collector: %v", err)
+		}
+
+		go gc.Run(c.ControllerManager.ConcurrentGCSyncs, stopCh)
+		glog.Infof("Started Kubernetes Garbage Collector")
+	})
 }
 
 func (c *MasterConfig) createSchedulerConfig() (*scheduler.Config, error) {
 	var policy schedulerapi.Policy
 	var configData []byte
 
-	configFactory := factory.NewConfigFactory(c.KubeClient)
+	configFactory := factory.NewConfigFactory(c.KubeClient, c.SharedInformers.Pods().Informer())
 	if _, err := os.Stat(c.Options.SchedulerConfigFile); err == nil {
 		configData, err = ioutil.ReadFile(c.Options.SchedulerConfigFile)
 		if err != nil {
@@ -171,9 +513,30 @@ func (c *MasterConfig) createSchedulerConfig() (*scheduler.Config, error) {
 			return nil, fmt.Errorf("invalid scheduler configuration: %v", err)
 		}
 
+		if len(policy.ExtenderConfigs) > 0 {
+			glog.Infof("Loaded %d scheduler extender(s) from %s", len(policy.ExtenderConfigs), c.Options.SchedulerConfigFile)
+		}
+
 		return configFactory.CreateFromConfig(policy)
 	}
 
 	// if the config file isn't provided, use the default provider
 	return configFactory.CreateFromProvider(factory.DefaultProvider)
 }
+
+// RegisterSchedulerPredicate adds a custom fit predicate under name so that it
+// participates in scheduling alongside the in-tree predicates the next time
+// RunScheduler builds its config. It must be called before RunScheduler so
+// downstream projects can add topology-, affinity-, or cost-aware placement
+// without forking the scheduler package.
+func (c *MasterConfig) RegisterSchedulerPredicate(name string, predicate algorithm.FitPredicate) {
+	factory.RegisterFitPredicate(name, predicate)
+}
+
+// RegisterSchedulerPriority adds a custom priority function under name,
+// weighted by weight, so that it participates in scheduling alongside the
+// in-tree priority functions the next time RunScheduler builds its config. It
+// must be called before RunScheduler.
+func (c *MasterConfig) RegisterSchedulerPriority(name string, priorityFunction algorithm.PriorityFunction, weight int) {
+	factory.RegisterPriorityFunction(name, priorityFunction, weight)
+}
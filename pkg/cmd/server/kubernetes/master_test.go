@@ -0,0 +1,134 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	fakecloud "k8s.io/kubernetes/pkg/cloudprovider/providers/fake"
+	"k8s.io/kubernetes/pkg/volume"
+)
+
+func TestRecyclableVolumeConfigOrDefault(t *testing.T) {
+	def := volume.RecyclableVolumeConfig{
+		ImageName: "openshift/recycler",
+		Command:   []string{"/usr/share/openshift/scripts/volumes/recycler.sh"},
+		Args:      []string{"/scrub"},
+		Timeout:   int64(60),
+	}
+
+	t.Run("unconfigured falls back to every default field", func(t *testing.T) {
+		got := recyclableVolumeConfigOrDefault(volume.RecyclableVolumeConfig{}, def)
+		if !reflect.DeepEqual(*got, def) {
+			t.Errorf("expected %+v, got %+v", def, *got)
+		}
+	})
+
+	t.Run("a partial override only replaces the fields that were set", func(t *testing.T) {
+		configured := volume.RecyclableVolumeConfig{Timeout: int64(120)}
+		got := recyclableVolumeConfigOrDefault(configured, def)
+
+		if got.Timeout != 120 {
+			t.Errorf("expected configured timeout 120 to override the default, got %d", got.Timeout)
+		}
+		if got.ImageName != def.ImageName {
+			t.Errorf("expected unset ImageName to fall back to default %q, got %q", def.ImageName, got.ImageName)
+		}
+		if !reflect.DeepEqual(got.Command, def.Command) {
+			t.Errorf("expected unset Command to fall back to default %v, got %v", def.Command, got.Command)
+		}
+		if !reflect.DeepEqual(got.Args, def.Args) {
+			t.Errorf("expected unset Args to fall back to default %v, got %v", def.Args, got.Args)
+		}
+	})
+}
+
+func TestDefaultPersistentVolumeRecyclerConfigsMergesPerField(t *testing.T) {
+	conf := VolumeConfiguration{
+		HostPathRecycler: volume.RecyclableVolumeConfig{
+			ImageName: "example.com/custom-recycler",
+			Timeout:   int64(120),
+		},
+	}
+
+	hostPathConfig, nfsConfig := defaultPersistentVolumeRecyclerConfigs("openshift/recycler", conf)
+
+	if hostPathConfig.ImageName != "example.com/custom-recycler" {
+		t.Errorf("expected configured ImageName to be used, got %q", hostPathConfig.ImageName)
+	}
+	if hostPathConfig.Timeout != 120 {
+		t.Errorf("expected configured Timeout 120 to reach the scrub pod config, got %d", hostPathConfig.Timeout)
+	}
+	wantCommand := []string{"/usr/share/openshift/scripts/volumes/recycler.sh"}
+	if !reflect.DeepEqual(hostPathConfig.Command, wantCommand) {
+		t.Errorf("expected unset Command to fall back to %v, got %v", wantCommand, hostPathConfig.Command)
+	}
+	wantArgs := []string{"/scrub"}
+	if !reflect.DeepEqual(hostPathConfig.Args, wantArgs) {
+		t.Errorf("expected unset Args to fall back to %v, got %v", wantArgs, hostPathConfig.Args)
+	}
+
+	if nfsConfig.ImageName != "openshift/recycler" {
+		t.Errorf("expected the unconfigured NFS recycler to keep the default image, got %q", nfsConfig.ImageName)
+	}
+	if nfsConfig.Timeout != 300 {
+		t.Errorf("expected the unconfigured NFS recycler to keep the default timeout 300, got %d", nfsConfig.Timeout)
+	}
+}
+
+func TestControllerHealthCheckHealthyWhileNotLeading(t *testing.T) {
+	check := &controllerHealthCheck{
+		name:       "test",
+		syncPeriod: time.Millisecond,
+		lastSync:   time.Now().Add(-time.Hour).UnixNano(),
+	}
+
+	// Stale well past 2*syncPeriod, but this process never became the
+	// leader, so a standby must not be reported as stuck.
+	if err := check.Check(nil); err != nil {
+		t.Errorf("expected a non-leading check to report healthy regardless of sync age, got %v", err)
+	}
+}
+
+func TestControllerHealthCheckUnhealthyWhenStaleWhileLeading(t *testing.T) {
+	check := &controllerHealthCheck{
+		name:       "test",
+		syncPeriod: time.Millisecond,
+	}
+	check.setLeading(true)
+	check.lastSync = time.Now().Add(-time.Hour).UnixNano()
+
+	if err := check.Check(nil); err == nil {
+		t.Error("expected a leading check with a stale sync to report unhealthy")
+	}
+}
+
+func TestControllerHealthCheckSetLeadingTrueResetsAge(t *testing.T) {
+	check := &controllerHealthCheck{
+		name:       "test",
+		syncPeriod: time.Hour,
+		lastSync:   time.Now().Add(-24 * time.Hour).UnixNano(),
+	}
+
+	check.setLeading(true)
+
+	if err := check.Check(nil); err != nil {
+		t.Errorf("expected setLeading(true) to reset the sync clock so a newly-leading check reports healthy, got %v", err)
+	}
+}
+
+func TestRunPersistentVolumeProvisionerSkipsWithoutCloudProvider(t *testing.T) {
+	c := &MasterConfig{}
+	// Should return immediately rather than attempting to build a
+	// provisioner controller when no cloud provider is configured.
+	c.RunPersistentVolumeProvisioner()
+}
+
+func TestRunPersistentVolumeProvisionerSkipsWhenProviderHasNoProvisioner(t *testing.T) {
+	c := &MasterConfig{CloudProvider: &fakecloud.FakeCloud{}}
+	// FakeCloud implements cloudprovider.Interface but none of the
+	// provisioner plugin interfaces, so NewPersistentVolumeProvisionerController
+	// should return a NoProvisionerFoundErr that this method swallows rather
+	// than treating as fatal.
+	c.RunPersistentVolumeProvisioner()
+}